@@ -0,0 +1,165 @@
+package geostore
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// AggregateCell summarizes the entities located within a single geobox cell: how many
+// there are, their centroid, and the geographic bounds of the cell itself.
+type AggregateCell struct {
+	Count    int
+	Centroid LatLng
+	Bounds   LatLngBounds
+}
+
+// AggregateByGrid buckets the entities of entityKind within viewbounds into the geobox
+// cells at the given geohash depth (1-MAXDEPTH), returning one AggregateCell per populated
+// cell. This is the geohash-grid aggregation pattern used to render heatmaps and marker
+// clusters at a zoom-appropriate resolution without shipping every entity to the client. If
+// viewbounds is smaller than a single cell at precision, the single containing cell's
+// aggregate is returned. Matching entities are appended to dst, the same way as
+// GetEntitiesByRegion, since entityKind's concrete type isn't known to this package.
+func (s Store) AggregateByGrid(viewbounds LatLngBounds, entityKind string, precision int, dst interface{}) (map[GeoBoxTag]AggregateCell, error) {
+	tags, err := geoBoxTagsAtPrecision(viewbounds, precision)
+	if err != nil {
+		return nil, err
+	}
+
+	backend := s.backend()
+	slice := reflect.ValueOf(dst).Elem()
+	cells := map[GeoBoxTag]AggregateCell{}
+
+	for _, tag := range tags {
+		bounds, err := geoBoxTagBounds(tag)
+		if err != nil {
+			return nil, err
+		}
+
+		before := slice.Len()
+		keys, err := backend.QueryByTag(entityKind, tag, dst)
+		if err != nil {
+			return nil, err
+		}
+		if len(keys) == 0 {
+			continue
+		}
+
+		var sumLat, sumLng float64
+		for i := before; i < slice.Len(); i++ {
+			l, ok := locatableAt(slice, i)
+			if !ok {
+				return nil, Error{errmsg: "geostore error: dst element does not implement Locatable"}
+			}
+			loc := l.GetLocation()
+			sumLat += loc.Lat
+			sumLng += loc.Lng
+		}
+
+		count := slice.Len() - before
+		cells[tag] = AggregateCell{
+			Count:    count,
+			Centroid: LatLng{Lat: sumLat / float64(count), Lng: sumLng / float64(count)},
+			Bounds:   bounds,
+		}
+	}
+
+	return cells, nil
+}
+
+// geoBoxTagsAtPrecision returns the geobox tags at the given fixed depth whose cells
+// intersect viewbounds. Unlike GeoBoxTagsFromViewBounds, which picks a depth based on the
+// size of viewbounds, depth here is supplied by the caller so that every tag has exactly
+// `precision` characters -- one bucket per cell, rather than a variable-depth
+// approximation. It handles dateline-crossing viewbounds the same way
+// GeoBoxTagsFromViewBounds does, by splitting and unioning. precision must be between 1 and
+// MAXDEPTH: GenerateGeoBoxTags never tags an entity with the empty (depth 0) hash, since the
+// first level of division already yields the 16 single-character cells, so a precision of 0
+// could never match anything.
+func geoBoxTagsAtPrecision(viewbounds LatLngBounds, precision int) ([]GeoBoxTag, error) {
+	if precision < 1 || precision > MAXDEPTH {
+		return nil, Error{errmsg: "geostore error: precision out of range in geoBoxTagsAtPrecision()"}
+	}
+
+	if viewbounds.NE.Lng < viewbounds.SW.Lng {
+		westbounds := LatLngBounds{
+			SW: viewbounds.SW,
+			NE: LatLng{Lat: viewbounds.NE.Lat, Lng: MAXLNG},
+		}
+		eastbounds := LatLngBounds{
+			SW: LatLng{Lat: viewbounds.SW.Lat, Lng: MINLNG},
+			NE: viewbounds.NE,
+		}
+
+		westtags, err := geoBoxTagsAtPrecision(westbounds, precision)
+		if err != nil {
+			return nil, err
+		}
+		easttags, err := geoBoxTagsAtPrecision(eastbounds, precision)
+		if err != nil {
+			return nil, err
+		}
+
+		return unionGeoBoxTags(westtags, easttags), nil
+	}
+
+	rootbox := LatLngBounds{NE: LatLng{Lat: MAXLAT, Lng: MAXLNG}, SW: LatLng{Lat: MINLAT, Lng: MINLNG}}
+	swhasher := &Geohasher{Point: viewbounds.SW, Box: rootbox}
+	nehasher := &Geohasher{Point: viewbounds.NE, Box: rootbox}
+	sehasher := &Geohasher{Point: LatLng{viewbounds.SW.Lat, viewbounds.NE.Lng}, Box: rootbox}
+	nwhasher := &Geohasher{Point: LatLng{viewbounds.NE.Lat, viewbounds.SW.Lng}, Box: rootbox}
+
+	for i := 0; i < precision; i++ {
+		if err := swhasher.Descend(); err != nil {
+			return nil, err
+		}
+		if err := nehasher.Descend(); err != nil {
+			return nil, err
+		}
+		if err := sehasher.Descend(); err != nil {
+			return nil, err
+		}
+		if err := nwhasher.Descend(); err != nil {
+			return nil, err
+		}
+	}
+
+	return enumerateGeoBoxTags(swhasher.GetHash(), nehasher.GetHash(), sehasher.GetHash(), nwhasher.GetHash())
+}
+
+// unionGeoBoxTags merges two sets of geobox tags, dropping duplicates and preserving the
+// order tags are first seen in.
+func unionGeoBoxTags(a, b []GeoBoxTag) []GeoBoxTag {
+	seen := map[GeoBoxTag]bool{}
+	union := []GeoBoxTag{}
+	for _, t := range append(a, b...) {
+		if !seen[t] {
+			seen[t] = true
+			union = append(union, t)
+		}
+	}
+	return union
+}
+
+// geoBoxTagBounds computes the LatLngBounds of the cell identified by tag, by replaying the
+// same 4x4 subdivision that GenerateGeoBoxTags used to produce it.
+func geoBoxTagBounds(tag GeoBoxTag) (LatLngBounds, error) {
+	box := LatLngBounds{NE: LatLng{Lat: MAXLAT, Lng: MAXLNG}, SW: LatLng{Lat: MINLAT, Lng: MINLNG}}
+
+	for i := 0; i < len(tag); i++ {
+		idx, err := strconv.ParseInt(string(tag[i]), 16, 0)
+		if err != nil {
+			return LatLngBounds{}, err
+		}
+
+		latSpacing := (box.NE.Lat - box.SW.Lat) / 4.0
+		lngSpacing := (box.NE.Lng - box.SW.Lng) / 4.0
+		row, col := idx/4, idx%4
+
+		sw := LatLng{Lat: box.SW.Lat + float64(row)*latSpacing, Lng: box.SW.Lng + float64(col)*lngSpacing}
+		ne := LatLng{Lat: sw.Lat + latSpacing, Lng: sw.Lng + lngSpacing}
+		box = LatLngBounds{SW: sw, NE: ne}
+	}
+
+	return box, nil
+}
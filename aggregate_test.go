@@ -0,0 +1,61 @@
+package geostore
+
+import "testing"
+
+func TestAggregateByGridRejectsPrecisionZero(t *testing.T) {
+	s := Store{Backend: NewMemoryBackend()}
+	viewbounds := LatLngBounds{SW: LatLng{Lat: -90, Lng: -180}, NE: LatLng{Lat: 90, Lng: 180}}
+
+	var dst []*testEntity
+	if _, err := s.AggregateByGrid(viewbounds, "Place", 0, &dst); err == nil {
+		t.Error("expected precision 0 to be rejected, since no entity is ever tagged at depth 0")
+	}
+}
+
+func TestAggregateByGridBucketsByCellAndComputesCentroid(t *testing.T) {
+	s := Store{Backend: NewMemoryBackend()}
+
+	// These two fall in the same top-level (precision 1) cell...
+	a := &testEntity{Location: LatLng{Lat: 10, Lng: 10}}
+	b := &testEntity{Location: LatLng{Lat: 20, Lng: 20}}
+	// ...and this one falls in a different cell.
+	c := &testEntity{Location: LatLng{Lat: -80, Lng: -170}}
+
+	for _, e := range []*testEntity{a, b, c} {
+		if err := s.StoreEntity("Place", e); err != nil {
+			t.Fatalf("StoreEntity returned error: %v", err)
+		}
+	}
+
+	viewbounds := LatLngBounds{SW: LatLng{Lat: -90, Lng: -180}, NE: LatLng{Lat: 44, Lng: 89}}
+	var dst []*testEntity
+	cells, err := s.AggregateByGrid(viewbounds, "Place", 1, &dst)
+	if err != nil {
+		t.Fatalf("AggregateByGrid returned error: %v", err)
+	}
+
+	var populated []AggregateCell
+	total := 0
+	for _, cell := range cells {
+		if cell.Count > 0 {
+			populated = append(populated, cell)
+			total += cell.Count
+		}
+	}
+	if total != 3 {
+		t.Fatalf("expected 3 entities total across populated cells, got %d", total)
+	}
+
+	foundPair := false
+	for _, cell := range populated {
+		if cell.Count == 2 {
+			foundPair = true
+			if cell.Centroid.Lat != 15 || cell.Centroid.Lng != 15 {
+				t.Errorf("expected centroid (15,15) for the 2-entity cell, got %v", cell.Centroid)
+			}
+		}
+	}
+	if !foundPair {
+		t.Errorf("expected one cell to contain both nearby entities, got %v", populated)
+	}
+}
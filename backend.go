@@ -0,0 +1,35 @@
+package geostore
+
+// Key identifies a stored entity independently of whatever concrete store a Backend
+// happens to wrap. Callers should treat a Key as an opaque token returned by a Backend to
+// pass back into later calls, not construct one by hand.
+type Key struct {
+	Kind string
+	ID   string
+}
+
+// Backend is the storage interface the geohashing logic in this package (Geohasher,
+// GenerateGeoBoxTags, GeoBoxTagsFromViewBounds) is built on. A Backend is responsible only
+// for persisting entities and running tag-based or unfiltered queries against a given
+// entity kind; it knows nothing about geohashing. This keeps the geohashing logic usable,
+// and unit-testable, outside of App Engine: see DatastoreBackend for the original App
+// Engine Datastore implementation and MemoryBackend for an in-memory one. Third-party
+// backends (Cloud Datastore, Firestore, BoltDB, Postgres with a text[] column, etc.) can be
+// added by implementing this interface. Note that Store.GetEntitiesByRegion and
+// Store.GetAllEntities only decode their results back into *datastore.Key, so they only work
+// against DatastoreBackend; against any other Backend, use Store.GetEntitiesByRegionKeys
+// (or GetEntitiesByDistance, GetEntitiesByRegionSorted, GetEntitiesByGeoJSONPolygon, which
+// already return the portable Key type).
+type Backend interface {
+	// Put generates entity's GeoBoxTags and stores it under the given kind, returning the
+	// Key it was stored under.
+	Put(kind string, entity Locatable) (Key, error)
+
+	// QueryByTag appends every entity of the given kind tagged with tag to dst, which must
+	// be a pointer to a slice of a type implementing Locatable, and returns their Keys.
+	QueryByTag(kind string, tag GeoBoxTag, dst interface{}) ([]Key, error)
+
+	// GetAll appends up to limit entities of the given kind to dst and returns their Keys.
+	// A limit of 0 or less means no limit.
+	GetAll(kind string, dst interface{}, limit int) ([]Key, error)
+}
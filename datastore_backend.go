@@ -0,0 +1,72 @@
+package geostore
+
+import (
+	"appengine"
+	"appengine/datastore"
+)
+
+// DatastoreBackend is the original Backend implementation, backed by App Engine's
+// Datastore. It is the Backend a zero-value Store falls back to when only Context is set,
+// which is what keeps the pre-Backend API, e.g. Store{Context: c}, working unchanged.
+type DatastoreBackend struct {
+	Context appengine.Context
+}
+
+func (d *DatastoreBackend) Put(kind string, entity Locatable) (Key, error) {
+	if err := GenerateGeoBoxTags(entity); err != nil {
+		return Key{}, err
+	}
+
+	k, err := datastore.Put(d.Context, datastore.NewIncompleteKey(d.Context, kind, nil), entity)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{Kind: kind, ID: k.Encode()}, nil
+}
+
+func (d *DatastoreBackend) QueryByTag(kind string, tag GeoBoxTag, dst interface{}) ([]Key, error) {
+	ks, err := datastore.NewQuery(kind).Filter("GeoBoxTags =", tag).GetAll(d.Context, dst)
+	if err != nil {
+		return nil, err
+	}
+	return datastoreKeysToKeys(kind, ks), nil
+}
+
+func (d *DatastoreBackend) GetAll(kind string, dst interface{}, limit int) ([]Key, error) {
+	q := datastore.NewQuery(kind)
+	// The Backend contract says a limit <= 0 means no limit, but datastore.Query.Limit(0)
+	// means "return zero results", so only call it when the caller actually wants one.
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	ks, err := q.GetAll(d.Context, dst)
+	if err != nil {
+		return nil, err
+	}
+	return datastoreKeysToKeys(kind, ks), nil
+}
+
+func datastoreKeysToKeys(kind string, ks []*datastore.Key) []Key {
+	keys := make([]Key, len(ks))
+	for i, k := range ks {
+		keys[i] = Key{Kind: kind, ID: k.Encode()}
+	}
+	return keys
+}
+
+// keysToDatastoreKeys decodes the Keys returned by a DatastoreBackend back into
+// *datastore.Key, for methods that keep the pre-Backend API's return type. It only works
+// when every Key's ID is a Datastore-encoded key, i.e. when the Store is Datastore-backed.
+func keysToDatastoreKeys(keys []Key) ([]*datastore.Key, error) {
+	dskeys := make([]*datastore.Key, len(keys))
+	for i, k := range keys {
+		dk, err := datastore.DecodeKey(k.ID)
+		if err != nil {
+			return nil, Error{errmsg: "geostore error: this method only returns *datastore.Key when Store is backed by Datastore; use the Key-returning equivalent for other backends"}
+		}
+		dskeys[i] = dk
+	}
+	return dskeys, nil
+}
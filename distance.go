@@ -0,0 +1,136 @@
+package geostore
+
+import (
+	"math"
+	"reflect"
+)
+
+// EarthRadiusMeters is the mean radius of the Earth, used for haversine distance
+// calculations and for converting a search radius into a bounding box.
+const EarthRadiusMeters float64 = 6371000.0
+
+// HaversineDistance returns the great-circle distance, in meters, between two LatLng
+// points using the haversine formula.
+func HaversineDistance(a, b LatLng) float64 {
+	phi1 := a.Lat * math.Pi / 180.0
+	phi2 := b.Lat * math.Pi / 180.0
+	dphi := (b.Lat - a.Lat) * math.Pi / 180.0
+	dlambda := (b.Lng - a.Lng) * math.Pi / 180.0
+
+	sinDphi := math.Sin(dphi / 2.0)
+	sinDlambda := math.Sin(dlambda / 2.0)
+	h := sinDphi*sinDphi + math.Cos(phi1)*math.Cos(phi2)*sinDlambda*sinDlambda
+
+	return 2.0 * EarthRadiusMeters * math.Asin(math.Sqrt(h))
+}
+
+// boundingBoxForRadius returns a LatLngBounds that circumscribes the circle of the given
+// radius, in meters, around center. The longitude delta widens toward the poles to
+// compensate for the narrowing of a degree of longitude. Latitude is clamped at the poles;
+// longitude wraps across the antimeridian, so the returned box may be dateline-crossing
+// (NE.Lng < SW.Lng). If the circle reaches a pole, the box spans all longitudes.
+func boundingBoxForRadius(center LatLng, radiusMeters float64) LatLngBounds {
+	dLat := (radiusMeters / EarthRadiusMeters) * (180.0 / math.Pi)
+
+	ne := LatLng{Lat: center.Lat + dLat}
+	sw := LatLng{Lat: center.Lat - dLat}
+
+	if ne.Lat > MAXLAT {
+		ne.Lat = MAXLAT
+	}
+	if sw.Lat < MINLAT {
+		sw.Lat = MINLAT
+	}
+
+	cosLat := math.Cos(center.Lat * math.Pi / 180.0)
+	if cosLat <= 0.000001 || ne.Lat >= MAXLAT || sw.Lat <= MINLAT {
+		// The circle reaches a pole (or center is effectively at one), so it spans all
+		// longitudes. Set this verbatim rather than running a 360 degree delta through the
+		// wrap-around clamp
+		// below, which would otherwise collapse it right back to a zero-width sliver at
+		// center.Lng.
+		sw.Lng = MINLNG
+		ne.Lng = MAXLNG
+		return LatLngBounds{NE: ne, SW: sw}
+	}
+
+	dLng := (radiusMeters / (EarthRadiusMeters * cosLat)) * (180.0 / math.Pi)
+	ne.Lng = center.Lng + dLng
+	sw.Lng = center.Lng - dLng
+
+	// Wrap longitude across the antimeridian rather than clamping, so a radius search
+	// centered near +/-180 still produces a usable (if dateline-crossing) box.
+	if ne.Lng > MAXLNG {
+		ne.Lng = ne.Lng - (MAXLNG - MINLNG)
+	}
+	if sw.Lng < MINLNG {
+		sw.Lng = sw.Lng + (MAXLNG - MINLNG)
+	}
+
+	return LatLngBounds{NE: ne, SW: sw}
+}
+
+// GetEntitiesByDistance returns the entities of entityKind within radiusMeters of center,
+// along with their distances from center, in meters. Candidates are first narrowed down to
+// those whose geoboxes intersect the bounding box that circumscribes the search radius (see
+// GeoBoxTagsFromViewBounds), then post-filtered with HaversineDistance, since a geobox is a
+// square approximation of the circular search area and will include some entities outside
+// the radius. Entities that don't survive the filter are removed from dst as well as from
+// the returned keys, so the two stay in step. Callers that want the results ordered by
+// proximity can sort them using the returned distances.
+func (s Store) GetEntitiesByDistance(center LatLng, radiusMeters float64, entityKind string, dst interface{}) ([]Key, []float64, error) {
+	viewbounds := boundingBoxForRadius(center, radiusMeters)
+
+	keys, err := s.getEntitiesByRegion(viewbounds, entityKind, dst)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return filterByDistance(dst, keys, center, radiusMeters)
+}
+
+// locatableAt returns the Locatable at index i of slice (which must hold, or point to,
+// values implementing Locatable).
+func locatableAt(slice reflect.Value, i int) (Locatable, bool) {
+	elem := slice.Index(i)
+	if l, ok := elem.Interface().(Locatable); ok {
+		return l, true
+	}
+	if elem.CanAddr() {
+		if l, ok := elem.Addr().Interface().(Locatable); ok {
+			return l, true
+		}
+	}
+	return nil, false
+}
+
+// filterByDistance removes every element of dst (and its corresponding key) whose location
+// is farther than radiusMeters from center, and returns the surviving keys together with
+// their distances from center, in the same order as the filtered dst.
+func filterByDistance(dst interface{}, keys []Key, center LatLng, radiusMeters float64) ([]Key, []float64, error) {
+	slice := reflect.ValueOf(dst).Elem()
+	if slice.Len() != len(keys) {
+		return nil, nil, Error{errmsg: "geostore error: dst and keys have mismatched lengths in filterByDistance()"}
+	}
+
+	filteredKeys := []Key{}
+	distances := []float64{}
+	kept := reflect.MakeSlice(slice.Type(), 0, slice.Len())
+
+	for i := 0; i < slice.Len(); i++ {
+		l, ok := locatableAt(slice, i)
+		if !ok {
+			return nil, nil, Error{errmsg: "geostore error: dst element does not implement Locatable"}
+		}
+
+		d := HaversineDistance(center, l.GetLocation())
+		if d <= radiusMeters {
+			filteredKeys = append(filteredKeys, keys[i])
+			distances = append(distances, d)
+			kept = reflect.Append(kept, slice.Index(i))
+		}
+	}
+
+	slice.Set(kept)
+	return filteredKeys, distances, nil
+}
@@ -0,0 +1,89 @@
+package geostore
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversineDistance(t *testing.T) {
+	// One degree of latitude is about 111.2km.
+	d := HaversineDistance(LatLng{Lat: 0, Lng: 0}, LatLng{Lat: 1, Lng: 0})
+	if math.Abs(d-111195.0) > 1000 {
+		t.Errorf("expected ~111195m between 0,0 and 1,0, got %v", d)
+	}
+
+	if d := HaversineDistance(LatLng{Lat: 12, Lng: 34}, LatLng{Lat: 12, Lng: 34}); d != 0 {
+		t.Errorf("expected 0 distance between identical points, got %v", d)
+	}
+}
+
+func TestBoundingBoxForRadiusSpansAllLongitudesAtThePoles(t *testing.T) {
+	b := boundingBoxForRadius(LatLng{Lat: 90, Lng: 0}, 50000)
+
+	if b.SW.Lng != MINLNG || b.NE.Lng != MAXLNG {
+		t.Errorf("expected a pole-centered radius box to span all longitudes, got SW.Lng=%v NE.Lng=%v", b.SW.Lng, b.NE.Lng)
+	}
+}
+
+func TestBoundingBoxForRadiusAwayFromPoles(t *testing.T) {
+	b := boundingBoxForRadius(LatLng{Lat: 0, Lng: 0}, 50000)
+
+	if b.SW.Lng == MINLNG && b.NE.Lng == MAXLNG {
+		t.Errorf("expected a box narrower than the whole world away from the poles, got SW.Lng=%v NE.Lng=%v", b.SW.Lng, b.NE.Lng)
+	}
+	if b.SW.Lng >= 0 || b.NE.Lng <= 0 {
+		t.Errorf("expected center to fall strictly inside the bounding box, got SW.Lng=%v NE.Lng=%v", b.SW.Lng, b.NE.Lng)
+	}
+}
+
+func TestGetEntitiesByDistanceFiltersOutOfRadiusCandidates(t *testing.T) {
+	s := Store{Backend: NewMemoryBackend()}
+
+	near := &testEntity{Location: LatLng{Lat: 0, Lng: 0}}
+	// Inside the radius's bounding box, so it is a geobox candidate, but ~63km away.
+	far := &testEntity{Location: LatLng{Lat: 0.4, Lng: 0.4}}
+	if err := s.StoreEntity("Place", near); err != nil {
+		t.Fatalf("StoreEntity returned error: %v", err)
+	}
+	if err := s.StoreEntity("Place", far); err != nil {
+		t.Fatalf("StoreEntity returned error: %v", err)
+	}
+
+	var dst []*testEntity
+	keys, distances, err := s.GetEntitiesByDistance(LatLng{Lat: 0, Lng: 0}, 50000, "Place", &dst)
+	if err != nil {
+		t.Fatalf("GetEntitiesByDistance returned error: %v", err)
+	}
+
+	if len(keys) != 1 || len(dst) != 1 || len(distances) != 1 {
+		t.Fatalf("expected exactly one entity within the radius, got %d keys, %d dst entries, %d distances", len(keys), len(dst), len(distances))
+	}
+	if dst[0] != near {
+		t.Errorf("expected the nearby entity to survive the filter, got %v", dst[0])
+	}
+}
+
+func TestGetEntitiesByDistanceFindsEntitiesNearAPole(t *testing.T) {
+	s := Store{Backend: NewMemoryBackend()}
+
+	// ~11km from the north pole: close enough that boundingBoxForRadius spans all
+	// longitudes, which used to make GeoBoxTagsFromViewBounds return only the depth-0 tag
+	// GeoBoxTag(""), a tag no entity is ever stored with.
+	near := &testEntity{Location: LatLng{Lat: 89.9, Lng: 10}}
+	if err := s.StoreEntity("Place", near); err != nil {
+		t.Fatalf("StoreEntity returned error: %v", err)
+	}
+
+	var dst []*testEntity
+	keys, distances, err := s.GetEntitiesByDistance(LatLng{Lat: 90, Lng: 0}, 50000, "Place", &dst)
+	if err != nil {
+		t.Fatalf("GetEntitiesByDistance returned error: %v", err)
+	}
+
+	if len(keys) != 1 || len(dst) != 1 || len(distances) != 1 {
+		t.Fatalf("expected the near-pole entity to be found, got %d keys, %d dst entries, %d distances", len(keys), len(dst), len(distances))
+	}
+	if dst[0] != near {
+		t.Errorf("expected the near-pole entity to survive the filter, got %v", dst[0])
+	}
+}
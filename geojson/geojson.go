@@ -0,0 +1,113 @@
+// Package geojson parses the subset of GeoJSON (RFC 7946) geostore needs to accept map
+// input -- Point, Polygon and BoundingBox -- and serializes stored entities back out as
+// Feature / FeatureCollection. It has no dependency on geostore itself, so it can also be
+// used as a plain GeoJSON helper when bridging to a frontend map library such as Leaflet or
+// Mapbox.
+package geojson
+
+import "errors"
+
+// Point is a GeoJSON Point geometry: a single [longitude, latitude] position.
+type Point struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// Lng returns the point's longitude.
+func (p Point) Lng() float64 { return p.Coordinates[0] }
+
+// Lat returns the point's latitude.
+func (p Point) Lat() float64 { return p.Coordinates[1] }
+
+// Polygon is a GeoJSON Polygon geometry: one or more linear rings of [longitude, latitude]
+// positions. The first ring is the exterior boundary; any further rings are holes, which
+// this package does not currently subtract when testing containment.
+type Polygon struct {
+	Type        string         `json:"type"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+}
+
+// BoundingBox returns the BoundingBox that circumscribes the polygon's exterior ring.
+func (p Polygon) BoundingBox() (BoundingBox, error) {
+	if len(p.Coordinates) == 0 || len(p.Coordinates[0]) == 0 {
+		return BoundingBox{}, errors.New("geojson: polygon has no exterior ring")
+	}
+
+	ring := p.Coordinates[0]
+	bb := BoundingBox{ring[0][0], ring[0][1], ring[0][0], ring[0][1]}
+	for _, c := range ring[1:] {
+		if c[0] < bb[0] {
+			bb[0] = c[0]
+		}
+		if c[1] < bb[1] {
+			bb[1] = c[1]
+		}
+		if c[0] > bb[2] {
+			bb[2] = c[0]
+		}
+		if c[1] > bb[3] {
+			bb[3] = c[1]
+		}
+	}
+	return bb, nil
+}
+
+// Contains reports whether the polygon's exterior ring contains the point at (lat, lng),
+// using a standard ray-casting point-in-polygon test.
+func (p Polygon) Contains(lat, lng float64) bool {
+	if len(p.Coordinates) == 0 {
+		return false
+	}
+	return rayCastContains(p.Coordinates[0], lat, lng)
+}
+
+func rayCastContains(ring [][2]float64, lat, lng float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+
+		crosses := (yi > lat) != (yj > lat)
+		if crosses && lng < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// BoundingBox is a GeoJSON bounding box: [minLng, minLat, maxLng, maxLat].
+type BoundingBox [4]float64
+
+func (b BoundingBox) MinLng() float64 { return b[0] }
+func (b BoundingBox) MinLat() float64 { return b[1] }
+func (b BoundingBox) MaxLng() float64 { return b[2] }
+func (b BoundingBox) MaxLat() float64 { return b[3] }
+
+// Feature is a GeoJSON Feature wrapping a single point geometry and an arbitrary set of
+// properties.
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   Point                  `json:"geometry"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// NewPointFeature builds a Feature geometry for the point at (lat, lng), attaching
+// properties alongside it.
+func NewPointFeature(lat, lng float64, properties map[string]interface{}) Feature {
+	return Feature{
+		Type:       "Feature",
+		Geometry:   Point{Type: "Point", Coordinates: [2]float64{lng, lat}},
+		Properties: properties,
+	}
+}
+
+// FeatureCollection is a GeoJSON FeatureCollection of Features.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// NewFeatureCollection wraps features in a FeatureCollection.
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
@@ -0,0 +1,71 @@
+package geojson
+
+import "testing"
+
+func squarePolygon() Polygon {
+	return Polygon{
+		Type: "Polygon",
+		Coordinates: [][][2]float64{
+			{
+				{-10, -10},
+				{10, -10},
+				{10, 10},
+				{-10, 10},
+				{-10, -10},
+			},
+		},
+	}
+}
+
+func TestPolygonBoundingBox(t *testing.T) {
+	bb, err := squarePolygon().BoundingBox()
+	if err != nil {
+		t.Fatalf("BoundingBox returned error: %v", err)
+	}
+
+	if bb.MinLng() != -10 || bb.MinLat() != -10 || bb.MaxLng() != 10 || bb.MaxLat() != 10 {
+		t.Errorf("expected bounding box (-10,-10)-(10,10), got %v", bb)
+	}
+}
+
+func TestPolygonBoundingBoxEmptyPolygon(t *testing.T) {
+	if _, err := (Polygon{}).BoundingBox(); err == nil {
+		t.Error("expected an error for a polygon with no exterior ring")
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	p := squarePolygon()
+
+	if !p.Contains(0, 0) {
+		t.Error("expected (0,0) to be inside the square")
+	}
+	if p.Contains(20, 20) {
+		t.Error("expected (20,20) to be outside the square")
+	}
+}
+
+func TestNewPointFeature(t *testing.T) {
+	f := NewPointFeature(12.5, -34.5, map[string]interface{}{"name": "somewhere"})
+
+	if f.Type != "Feature" {
+		t.Errorf("expected type Feature, got %v", f.Type)
+	}
+	if f.Geometry.Lat() != 12.5 || f.Geometry.Lng() != -34.5 {
+		t.Errorf("expected geometry (12.5,-34.5), got (%v,%v)", f.Geometry.Lat(), f.Geometry.Lng())
+	}
+	if f.Properties["name"] != "somewhere" {
+		t.Errorf("expected properties to carry through, got %v", f.Properties)
+	}
+}
+
+func TestNewFeatureCollection(t *testing.T) {
+	fc := NewFeatureCollection([]Feature{NewPointFeature(0, 0, nil)})
+
+	if fc.Type != "FeatureCollection" {
+		t.Errorf("expected type FeatureCollection, got %v", fc.Type)
+	}
+	if len(fc.Features) != 1 {
+		t.Errorf("expected 1 feature, got %d", len(fc.Features))
+	}
+}
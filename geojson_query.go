@@ -0,0 +1,65 @@
+package geostore
+
+import (
+	"reflect"
+
+	"github.com/HershReddy/geostore/geojson"
+)
+
+// ToGeoJSONFeature serializes a stored Locatable entity's location as a GeoJSON Point
+// Feature, attaching properties alongside it.
+func ToGeoJSONFeature(l Locatable, properties map[string]interface{}) geojson.Feature {
+	loc := l.GetLocation()
+	return geojson.NewPointFeature(loc.Lat, loc.Lng, properties)
+}
+
+// GetEntitiesByGeoJSONPolygon returns the entities of entityKind whose location falls
+// within poly. Candidates are first narrowed down to those whose geoboxes intersect poly's
+// bounding box (see GeoBoxTagsFromViewBounds), then post-filtered with a ray-casting
+// point-in-polygon test, since a bounding box only approximates the polygon's shape.
+func (s Store) GetEntitiesByGeoJSONPolygon(poly geojson.Polygon, entityKind string, dst interface{}) ([]Key, error) {
+	bb, err := poly.BoundingBox()
+	if err != nil {
+		return nil, err
+	}
+
+	viewbounds := LatLngBounds{
+		SW: LatLng{Lat: bb.MinLat(), Lng: bb.MinLng()},
+		NE: LatLng{Lat: bb.MaxLat(), Lng: bb.MaxLng()},
+	}
+
+	keys, err := s.getEntitiesByRegion(viewbounds, entityKind, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterByPolygon(dst, keys, poly)
+}
+
+// filterByPolygon removes every element of dst (and its corresponding key) whose location
+// falls outside poly.
+func filterByPolygon(dst interface{}, keys []Key, poly geojson.Polygon) ([]Key, error) {
+	slice := reflect.ValueOf(dst).Elem()
+	if slice.Len() != len(keys) {
+		return nil, Error{errmsg: "geostore error: dst and keys have mismatched lengths in filterByPolygon()"}
+	}
+
+	filteredKeys := []Key{}
+	kept := reflect.MakeSlice(slice.Type(), 0, slice.Len())
+
+	for i := 0; i < slice.Len(); i++ {
+		l, ok := locatableAt(slice, i)
+		if !ok {
+			return nil, Error{errmsg: "geostore error: dst element does not implement Locatable"}
+		}
+
+		loc := l.GetLocation()
+		if poly.Contains(loc.Lat, loc.Lng) {
+			filteredKeys = append(filteredKeys, keys[i])
+			kept = reflect.Append(kept, slice.Index(i))
+		}
+	}
+
+	slice.Set(kept)
+	return filteredKeys, nil
+}
@@ -40,7 +40,6 @@ import (
 	"appengine"
 	"appengine/datastore"
 	"fmt"
-	"log"
 	"math"
 	"strconv"
 )
@@ -95,8 +94,23 @@ type Locatable interface {
 	ClearGeoBoxTags()
 }
 
+// Store is the original, App Engine-specific entry point into this package. Setting
+// Context alone, as in Store{Context: c}, keeps working exactly as before and queries
+// Datastore directly via DatastoreBackend. New code, or code that wants to run against a
+// different Backend (see MemoryBackend), should set Backend instead; Context is then
+// ignored.
 type Store struct {
 	Context appengine.Context
+	Backend Backend
+}
+
+// backend returns the Backend this Store should use: the one explicitly set, or a
+// DatastoreBackend over Context otherwise.
+func (s Store) backend() Backend {
+	if s.Backend != nil {
+		return s.Backend
+	}
+	return &DatastoreBackend{Context: s.Context}
 }
 
 type Geohasher struct {
@@ -187,46 +201,68 @@ func (g *Geohasher) Descend() error {
 	return nil
 }
 
+// GetEntitiesByRegion keeps returning []*datastore.Key, as it always has, so that existing
+// callers that encode, store, or otherwise pass on the keys it returns keep compiling
+// unchanged. That's only meaningful when the Store is actually Datastore-backed: against any
+// other Backend, decoding a Key.ID back into a *datastore.Key always fails, so every call
+// returns an error and discards the already-fetched results. Code that wants to run the same
+// region query against any Backend should call GetEntitiesByRegionKeys instead;
+// GetEntitiesByDistance, GetEntitiesByRegionSorted and GetEntitiesByGeoJSONPolygon use the
+// same Key-returning implementation, getEntitiesByRegion, internally.
 func (s Store) GetEntitiesByRegion(viewbounds LatLngBounds, entityKind string, dst interface{}) ([]*datastore.Key, error) {
+	keys, err := s.getEntitiesByRegion(viewbounds, entityKind, dst)
+	if err != nil {
+		return nil, err
+	}
+	return keysToDatastoreKeys(keys)
+}
+
+// GetEntitiesByRegionKeys is the Backend-generic sibling of GetEntitiesByRegion: it returns
+// the portable Key type instead of *datastore.Key, so it works against MemoryBackend and any
+// other non-Datastore Backend, not just DatastoreBackend.
+func (s Store) GetEntitiesByRegionKeys(viewbounds LatLngBounds, entityKind string, dst interface{}) ([]Key, error) {
+	return s.getEntitiesByRegion(viewbounds, entityKind, dst)
+}
+
+// getEntitiesByRegion is the Backend-generic implementation GetEntitiesByRegion and
+// GetEntitiesByRegionKeys wrap.
+func (s Store) getEntitiesByRegion(viewbounds LatLngBounds, entityKind string, dst interface{}) ([]Key, error) {
 
 	geoboxtags, err := GeoBoxTagsFromViewBounds(viewbounds)
 	if err != nil {
 		return nil, err
 	}
 
-	keys := []*datastore.Key{}
+	backend := s.backend()
+	keys := []Key{}
 	for _, geoboxtag := range geoboxtags {
-		ks, err := datastore.NewQuery(entityKind).Filter("GeoBoxTags =", geoboxtag).GetAll(s.Context, dst)
+		ks, err := backend.QueryByTag(entityKind, geoboxtag, dst)
 		if err != nil {
 			return nil, err
 		}
 		keys = append(keys, ks...)
 	}
 
-	return keys, nil
+	// An entity tagged with more than one of the geoboxtags above (which happens whenever
+	// its geobox tags include two tags from the same query set) would otherwise come back
+	// once per matching tag, so dedupe before returning.
+	return dedupeByKey(dst, keys)
 }
 
+// GetAllEntities keeps returning []*datastore.Key for the same backward-compatibility
+// reason as GetEntitiesByRegion above.
 func (s Store) GetAllEntities(entityKind string, dst interface{}, limit int) ([]*datastore.Key, error) {
 	// log.Printf("getting all entities of type %v", entityKind)
-	keys, err := datastore.NewQuery(entityKind).Limit(limit).GetAll(s.Context, dst)
-	return keys, err
-}
-
-func (s Store) StoreEntity(entityKind string, entity Locatable) error {
-
-	err := GenerateGeoBoxTags(entity)
-	if err != nil {
-		return err
-	}
-
-	// log.Printf("Storing entity %v: %v", entityKind, entity)
-
-	_, err = datastore.Put(s.Context, datastore.NewIncompleteKey(s.Context, entityKind, nil), entity)
+	keys, err := s.backend().GetAll(entityKind, dst, limit)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return keysToDatastoreKeys(keys)
+}
 
-	return nil
+func (s Store) StoreEntity(entityKind string, entity Locatable) error {
+	_, err := s.backend().Put(entityKind, entity)
+	return err
 }
 
 func GenerateGeoBoxTags(l Locatable) error {
@@ -260,8 +296,33 @@ func GenerateGeoBoxTags(l Locatable) error {
 	return nil
 }
 
-// Finds the GeoBoxes (i.e. cells) that are the closest approximation to the given LatLngBounds
+// Finds the GeoBoxes (i.e. cells) that are the closest approximation to the given LatLngBounds.
+// If viewbounds crosses the +/-180 degree antimeridian (detected by NE.Lng < SW.Lng), it is
+// split into the two sub-boxes [SW.Lng, 180] and [-180, NE.Lng], each is resolved to geobox
+// tags independently, and the two tag sets are unioned.
 func GeoBoxTagsFromViewBounds(viewbounds LatLngBounds) ([]GeoBoxTag, error) {
+	if viewbounds.NE.Lng < viewbounds.SW.Lng {
+		westbounds := LatLngBounds{
+			SW: viewbounds.SW,
+			NE: LatLng{Lat: viewbounds.NE.Lat, Lng: MAXLNG},
+		}
+		eastbounds := LatLngBounds{
+			SW: LatLng{Lat: viewbounds.SW.Lat, Lng: MINLNG},
+			NE: viewbounds.NE,
+		}
+
+		westtags, err := GeoBoxTagsFromViewBounds(westbounds)
+		if err != nil {
+			return nil, err
+		}
+		easttags, err := GeoBoxTagsFromViewBounds(eastbounds)
+		if err != nil {
+			return nil, err
+		}
+
+		return unionGeoBoxTags(westtags, easttags), nil
+	}
+
 	var err error
 	swhasher := &Geohasher{
 		Point: viewbounds.SW,
@@ -319,10 +380,29 @@ func GeoBoxTagsFromViewBounds(viewbounds LatLngBounds) ([]GeoBoxTag, error) {
 		}
 	}
 
-	swhash := swhasher.GetHash()
-	nehash := nehasher.GetHash()
-	sehash := sehasher.GetHash()
-	nwhash := nwhasher.GetHash()
+	return enumerateGeoBoxTags(swhasher.GetHash(), nehasher.GetHash(), sehasher.GetHash(), nwhasher.GetHash())
+}
+
+// enumerateGeoBoxTags walks the grid of geoboxes bounded by the SW, NE, SE and NW corner
+// hashes (which must all be at the same depth) and returns the tag of every cell in that
+// grid. This is the shared tail of both GeoBoxTagsFromViewBounds, whose corner hashes are
+// descended to a depth chosen by the size of the viewbounds, and geoBoxTagsAtPrecision,
+// whose corner hashes are descended to a fixed, caller-supplied depth.
+func enumerateGeoBoxTags(swhash, nehash, sehash, nwhash string) ([]GeoBoxTag, error) {
+	// A viewbounds at least as large as a top-level cell never descends past depth 0, so all
+	// four corner hashes come back empty. The empty string isn't a geobox tag any entity is
+	// ever stored with (GenerateGeoBoxTags starts at depth 1), so treating it like any other
+	// single-cell hash below would silently match nothing; the whole top-level grid -- all 16
+	// cells -- is the correct covering set instead.
+	if len(swhash) == 0 {
+		alltags := make([]GeoBoxTag, 0, 16)
+		for _, row := range CODES {
+			for _, code := range row {
+				alltags = append(alltags, GeoBoxTag(code))
+			}
+		}
+		return alltags, nil
+	}
 
 	// This case will occur only if the region being viewed is strictly within a singe geobox (cell).
 	// In this case we return the hash for that geobox to the caller, as the entire viewbounds is in that cell.
@@ -394,9 +474,14 @@ func GetNorthBoxTag(boxtag string) (string, error) {
 	boxnorthint := boxint + 4
 	if boxnorthint >= 16 {
 		boxnorthint = boxnorthint - 16
-		prefix, err = GetNorthBoxTag(prefix)
-		if err != nil {
-			return "", err
+		// At the top-level tag (empty prefix) there's no parent box to carry into; the
+		// wrapped row at this character is the whole answer, matching the GetEastBoxTag fix
+		// in 0bcd22d.
+		if prefix != "" {
+			prefix, err = GetNorthBoxTag(prefix)
+			if err != nil {
+				return "", err
+			}
 		}
 	}
 	boxnorthcode := CODES[boxnorthint/4][boxnorthint%4]
@@ -417,9 +502,13 @@ func GetEastBoxTag(boxtag string) (string, error) {
 	boxeastint := boxint + 1
 	if (boxint%4)+1 >= 4 {
 		boxeastint = boxeastint - 4
-		prefix, err = GetEastBoxTag(prefix)
-		if err != nil {
-			return "", err
+		// Longitude wraps around the globe, so at the top-level tag (empty prefix) there's
+		// no parent box to carry into: wrapping within this character is the whole answer.
+		if prefix != "" {
+			prefix, err = GetEastBoxTag(prefix)
+			if err != nil {
+				return "", err
+			}
 		}
 	}
 	boxeastcode := CODES[boxeastint/4][boxeastint%4]
@@ -0,0 +1,107 @@
+package geostore
+
+import "testing"
+
+// testEntity is a minimal Locatable used across this package's tests, run against
+// MemoryBackend so they don't need an App Engine context.
+type testEntity struct {
+	Location   LatLng
+	GeoBoxTags []GeoBoxTag
+}
+
+func (e *testEntity) SetLocation(l LatLng)       { e.Location = l }
+func (e *testEntity) GetLocation() LatLng        { return e.Location }
+func (e *testEntity) GetGeoBoxTags() []GeoBoxTag { return e.GeoBoxTags }
+func (e *testEntity) AddGeoBoxTag(t GeoBoxTag)   { e.GeoBoxTags = append(e.GeoBoxTags, t) }
+func (e *testEntity) ClearGeoBoxTags()           { e.GeoBoxTags = nil }
+
+func TestGeoBoxTagsFromViewBoundsDatelineCrossing(t *testing.T) {
+	viewbounds := LatLngBounds{
+		SW: LatLng{Lat: -10, Lng: 170},
+		NE: LatLng{Lat: 10, Lng: -170},
+	}
+
+	tags, err := GeoBoxTagsFromViewBounds(viewbounds)
+	if err != nil {
+		t.Fatalf("GeoBoxTagsFromViewBounds returned error: %v", err)
+	}
+	if len(tags) == 0 {
+		t.Fatal("expected dateline-crossing viewbounds to produce geobox tags, got none")
+	}
+
+	// A point just east of the antimeridian, inside viewbounds, should be covered by one of
+	// the returned tags.
+	g := &Geohasher{
+		Point: LatLng{Lat: 0, Lng: -179},
+		Box:   LatLngBounds{NE: LatLng{Lat: MAXLAT, Lng: MAXLNG}, SW: LatLng{Lat: MINLAT, Lng: MINLNG}},
+	}
+	for g.GetDepth() < len(tags[0]) {
+		if err := g.Descend(); err != nil {
+			t.Fatalf("Descend() returned error: %v", err)
+		}
+	}
+
+	found := false
+	for _, tag := range tags {
+		if string(tag) == g.GetHash() {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("expected geobox tag %q (covering a point east of the antimeridian) in %v", g.GetHash(), tags)
+	}
+}
+
+func TestGeoBoxTagsFromViewBoundsNearNorthPole(t *testing.T) {
+	// An ordinary, non-dateline-crossing viewbounds reaching toward the north pole. Descending
+	// into it used to carry all the way up through GetNorthBoxTag's recursive prefix handling
+	// to the empty (top-level) tag and error out.
+	viewbounds := LatLngBounds{
+		SW: LatLng{Lat: 46, Lng: 85},
+		NE: LatLng{Lat: 89, Lng: 95},
+	}
+
+	tags, err := GeoBoxTagsFromViewBounds(viewbounds)
+	if err != nil {
+		t.Fatalf("GeoBoxTagsFromViewBounds returned error: %v", err)
+	}
+	if len(tags) == 0 {
+		t.Fatal("expected a north-pole-reaching viewbounds to produce geobox tags, got none")
+	}
+}
+
+func TestDedupeByKeyCollapsesDuplicates(t *testing.T) {
+	dst := []*testEntity{
+		{Location: LatLng{Lat: 1, Lng: 1}},
+		{Location: LatLng{Lat: 2, Lng: 2}},
+	}
+	keys := []Key{{Kind: "Place", ID: "1"}, {Kind: "Place", ID: "1"}}
+
+	deduped, err := dedupeByKey(&dst, keys)
+	if err != nil {
+		t.Fatalf("dedupeByKey returned error: %v", err)
+	}
+	if len(deduped) != 1 || len(dst) != 1 {
+		t.Fatalf("expected dedupeByKey to collapse the duplicate key, got %d keys and %d dst entries", len(deduped), len(dst))
+	}
+}
+
+func TestGetEntitiesByRegionKeysDedupesAcrossBackend(t *testing.T) {
+	s := Store{Backend: NewMemoryBackend()}
+
+	entity := &testEntity{Location: LatLng{Lat: 10, Lng: 10}}
+	if err := s.StoreEntity("Place", entity); err != nil {
+		t.Fatalf("StoreEntity returned error: %v", err)
+	}
+
+	viewbounds := LatLngBounds{SW: LatLng{Lat: 5, Lng: 5}, NE: LatLng{Lat: 15, Lng: 15}}
+	var dst []*testEntity
+	keys, err := s.GetEntitiesByRegionKeys(viewbounds, "Place", &dst)
+	if err != nil {
+		t.Fatalf("GetEntitiesByRegionKeys returned error: %v", err)
+	}
+	if len(keys) != 1 || len(dst) != 1 {
+		t.Fatalf("expected exactly one result, got %d keys and %d dst entries", len(keys), len(dst))
+	}
+}
@@ -0,0 +1,81 @@
+package geostore
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// MemoryBackend is an in-memory Backend implementation. It exists primarily so the
+// geohashing logic in this package can be exercised without an App Engine context, but it
+// is also a reasonable Backend for tests and small, single-process deployments.
+type MemoryBackend struct {
+	mu       sync.Mutex
+	nextID   int64
+	entities map[string]map[string]Locatable // kind -> ID -> entity
+}
+
+// NewMemoryBackend returns an empty, ready-to-use MemoryBackend.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{entities: map[string]map[string]Locatable{}}
+}
+
+func (m *MemoryBackend) Put(kind string, entity Locatable) (Key, error) {
+	if err := GenerateGeoBoxTags(entity); err != nil {
+		return Key{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	id := fmt.Sprintf("%d", m.nextID)
+	if m.entities[kind] == nil {
+		m.entities[kind] = map[string]Locatable{}
+	}
+	m.entities[kind][id] = entity
+
+	return Key{Kind: kind, ID: id}, nil
+}
+
+func (m *MemoryBackend) QueryByTag(kind string, tag GeoBoxTag, dst interface{}) ([]Key, error) {
+	return m.query(kind, dst, 0, func(l Locatable) bool {
+		for _, t := range l.GetGeoBoxTags() {
+			if t == tag {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (m *MemoryBackend) GetAll(kind string, dst interface{}, limit int) ([]Key, error) {
+	return m.query(kind, dst, limit, func(l Locatable) bool { return true })
+}
+
+func (m *MemoryBackend) query(kind string, dst interface{}, limit int, match func(Locatable) bool) ([]Key, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	slice := reflect.ValueOf(dst).Elem()
+	elemIsPtr := slice.Type().Elem().Kind() == reflect.Ptr
+
+	keys := []Key{}
+	for id, entity := range m.entities[kind] {
+		if limit > 0 && len(keys) >= limit {
+			break
+		}
+		if !match(entity) {
+			continue
+		}
+
+		elem := reflect.ValueOf(entity)
+		if !elemIsPtr {
+			elem = elem.Elem()
+		}
+		slice.Set(reflect.Append(slice, elem))
+		keys = append(keys, Key{Kind: kind, ID: id})
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,114 @@
+package geostore
+
+import (
+	"reflect"
+	"sort"
+)
+
+// RegionQueryOptions configures the ranking and limiting behavior of
+// GetEntitiesByRegionSorted.
+type RegionQueryOptions struct {
+	// SortByDistanceFrom, when non-nil, orders the results by ascending haversine distance
+	// from this point.
+	SortByDistanceFrom *LatLng
+
+	// TopN, when greater than zero, limits the results to the nearest N entities. TopN is
+	// only meaningful when SortByDistanceFrom is also set; it is ignored otherwise.
+	TopN int
+}
+
+// GetEntitiesByRegionSorted is a sibling of GetEntitiesByRegion that can additionally rank
+// the results by proximity to a reference point and limit them to the nearest TopN, similar
+// to a "sort by distance" search feature.
+func (s Store) GetEntitiesByRegionSorted(viewbounds LatLngBounds, entityKind string, dst interface{}, opts RegionQueryOptions) ([]Key, error) {
+	keys, err := s.getEntitiesByRegion(viewbounds, entityKind, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.SortByDistanceFrom == nil {
+		return keys, nil
+	}
+
+	keys, err = sortByDistance(dst, keys, *opts.SortByDistanceFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.TopN > 0 && opts.TopN < len(keys) {
+		keys, err = truncate(dst, keys, opts.TopN)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// dedupeByKey removes duplicate entries from keys and their corresponding elements in dst,
+// keeping the first occurrence of each key.
+func dedupeByKey(dst interface{}, keys []Key) ([]Key, error) {
+	slice := reflect.ValueOf(dst).Elem()
+	if slice.Len() != len(keys) {
+		return nil, Error{errmsg: "geostore error: dst and keys have mismatched lengths in dedupeByKey()"}
+	}
+
+	seen := map[Key]bool{}
+	dedupedKeys := make([]Key, 0, len(keys))
+	kept := reflect.MakeSlice(slice.Type(), 0, slice.Len())
+
+	for i, k := range keys {
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		dedupedKeys = append(dedupedKeys, k)
+		kept = reflect.Append(kept, slice.Index(i))
+	}
+
+	slice.Set(kept)
+	return dedupedKeys, nil
+}
+
+// sortByDistance reorders dst in order of ascending distance from center and returns keys
+// reordered to match, as a new slice.
+func sortByDistance(dst interface{}, keys []Key, center LatLng) ([]Key, error) {
+	slice := reflect.ValueOf(dst).Elem()
+	if slice.Len() != len(keys) {
+		return nil, Error{errmsg: "geostore error: dst and keys have mismatched lengths in sortByDistance()"}
+	}
+
+	type rankedIndex struct {
+		index    int
+		distance float64
+	}
+	ranked := make([]rankedIndex, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		l, ok := locatableAt(slice, i)
+		if !ok {
+			return nil, Error{errmsg: "geostore error: dst element does not implement Locatable"}
+		}
+		ranked[i] = rankedIndex{index: i, distance: HaversineDistance(center, l.GetLocation())}
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		return ranked[i].distance < ranked[j].distance
+	})
+
+	sortedKeys := make([]Key, len(keys))
+	sorted := reflect.MakeSlice(slice.Type(), 0, slice.Len())
+	for i, r := range ranked {
+		sortedKeys[i] = keys[r.index]
+		sorted = reflect.Append(sorted, slice.Index(r.index))
+	}
+
+	slice.Set(sorted)
+	return sortedKeys, nil
+}
+
+// truncate keeps only the first n elements of dst and keys.
+func truncate(dst interface{}, keys []Key, n int) ([]Key, error) {
+	slice := reflect.ValueOf(dst).Elem()
+	slice.Set(slice.Slice(0, n))
+	return keys[:n], nil
+}
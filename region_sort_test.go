@@ -0,0 +1,37 @@
+package geostore
+
+import "testing"
+
+func TestGetEntitiesByRegionSortedOrdersByDistanceAndLimitsToTopN(t *testing.T) {
+	s := Store{Backend: NewMemoryBackend()}
+
+	center := LatLng{Lat: 0, Lng: 0}
+	near := &testEntity{Location: LatLng{Lat: 1, Lng: 0}}
+	mid := &testEntity{Location: LatLng{Lat: 3, Lng: 0}}
+	far := &testEntity{Location: LatLng{Lat: 5, Lng: 0}}
+
+	// Store in an order that doesn't match distance from center, so a passing test can't be
+	// an accident of insertion order.
+	for _, e := range []*testEntity{far, near, mid} {
+		if err := s.StoreEntity("Place", e); err != nil {
+			t.Fatalf("StoreEntity returned error: %v", err)
+		}
+	}
+
+	viewbounds := LatLngBounds{SW: LatLng{Lat: -10, Lng: -10}, NE: LatLng{Lat: 10, Lng: 10}}
+	var dst []*testEntity
+	keys, err := s.GetEntitiesByRegionSorted(viewbounds, "Place", &dst, RegionQueryOptions{
+		SortByDistanceFrom: &center,
+		TopN:               2,
+	})
+	if err != nil {
+		t.Fatalf("GetEntitiesByRegionSorted returned error: %v", err)
+	}
+
+	if len(keys) != 2 || len(dst) != 2 {
+		t.Fatalf("expected TopN to limit results to 2, got %d keys and %d dst entries", len(keys), len(dst))
+	}
+	if dst[0] != near || dst[1] != mid {
+		t.Errorf("expected results ordered [near, mid], got [%v, %v]", dst[0].Location, dst[1].Location)
+	}
+}